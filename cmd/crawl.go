@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/retgits/fdio/crawler"
+	"github.com/retgits/fdio/database"
+	"github.com/retgits/fdio/httpcache"
+	"github.com/spf13/cobra"
+)
+
+// crawlWorkers is the default number of goroutines that fetch raw
+// activity.json/trigger.json content concurrently.
+const crawlWorkers = 5
+
+// crawlRateLimitBuffer is the default number of remaining API requests we
+// want to keep in reserve before throttling, on forges that expose one.
+const crawlRateLimitBuffer = 100
+
+// insertBatchSize controls how many prepared items the collector
+// accumulates before writing a batch to the database.
+const insertBatchSize = 25
+
+// crawlCacheTTL is the default lifetime of a cached raw-content response
+// before it is treated as a miss regardless of what the forge says.
+const crawlCacheTTL = 24 * time.Hour
+
+var (
+	crawlWorkerCount    int
+	crawlRateLimitAlloc int
+	crawlContribType    string
+	crawlForge          string
+	crawlCacheDir       string
+	crawlCacheTTLFlag   time.Duration
+)
+
+// crawlCmd searches a forge for activity.json/trigger.json files related
+// to Flogo and stores what it finds in the database.
+var crawlCmd = &cobra.Command{
+	Use:   "crawl",
+	Short: "Crawl a forge for Flogo activities and triggers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := httpcache.New(crawlCacheDir, crawlCacheTTLFlag)
+		if err != nil {
+			return err
+		}
+
+		c, err := crawler.New(crawlForge, githubToken, crawlRateLimitAlloc, cache, logger)
+		if err != nil {
+			return err
+		}
+
+		db, err := database.New(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		// Cancel the crawl on Ctrl-C instead of killing the process outright,
+		// so the collector goroutine gets a chance to flush whatever batch it
+		// is holding before Crawl returns.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		return Crawl(ctx, c, db, 0, crawlContribType, crawlWorkerCount)
+	},
+}
+
+func init() {
+	crawlCmd.Flags().IntVar(&crawlWorkerCount, "workers", crawlWorkers, "number of concurrent workers fetching raw content")
+	crawlCmd.Flags().IntVar(&crawlRateLimitAlloc, "rate-limit-buffer", crawlRateLimitBuffer, "number of API requests to keep in reserve before throttling")
+	crawlCmd.Flags().StringVar(&crawlContribType, "type", "Activity", "type of contribution to search for, Activity or Trigger")
+	crawlCmd.Flags().StringVar(&crawlForge, "forge", "github", "forge to crawl: github, gitlab or gitea")
+	crawlCmd.Flags().StringVar(&crawlCacheDir, "cache-dir", defaultCacheDir(), "directory to store cached raw content in, to avoid re-fetching unchanged files")
+	crawlCmd.Flags().DurationVar(&crawlCacheTTLFlag, "cache-ttl", crawlCacheTTL, "how long a cached response is trusted before it is re-fetched regardless of the forge's response")
+}
+
+// defaultCacheDir returns ~/.fdio/httpcache, falling back to a relative
+// path if the user's home directory cannot be determined.
+func defaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".fdio", "httpcache")
+	}
+	return filepath.Join(home, ".fdio", "httpcache")
+}
+
+// Crawl searches a forge for activity.json or trigger.json files related to
+// Flogo. A single producer goroutine walks the forge's search results while
+// a pool of workers fetches the raw content of every hit in parallel; a
+// collector goroutine batches the prepared results and writes them to the
+// database.
+func Crawl(ctx context.Context, c crawler.Crawler, db *database.Database, timeout float64, contribType string, workers int) error {
+	if workers < 1 {
+		workers = crawlWorkers
+	}
+
+	filename := "activity.json"
+	if contribType == "Trigger" {
+		filename = "trigger.json"
+	}
+
+	hits := make(chan crawler.FileHit, workers*4)
+	results := make(chan map[string]interface{}, workers*4)
+	errs := make(chan error, 1)
+
+	var producerWG sync.WaitGroup
+	producerWG.Add(1)
+	go func() {
+		defer producerWG.Done()
+		defer close(hits)
+		if err := produceSearchHits(ctx, c, filename, timeout, hits); err != nil {
+			select {
+			case errs <- err:
+			default:
+			}
+		}
+	}()
+
+	var workerWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workerWG.Add(1)
+		go func() {
+			defer workerWG.Done()
+			for hit := range hits {
+				start := time.Now()
+				result, err := prepareItem(ctx, c, hit)
+				durationMS := time.Since(start).Milliseconds()
+
+				switch {
+				case err != nil:
+					logger.Error("processing item failed", "repo", hit.RepoFullName, "path", hit.Path, "contrib_type", filename, "outcome", "error", "error", err.Error(), "duration_ms", durationMS)
+				case result == nil:
+					logger.Info("skipped item", "repo", hit.RepoFullName, "path", hit.Path, "contrib_type", filename, "outcome", "skipped", "duration_ms", durationMS)
+				default:
+					logger.Info("added item", "repo", hit.RepoFullName, "path", hit.Path, "contrib_type", filename, "outcome", "added", "duration_ms", durationMS)
+					results <- result
+				}
+			}
+		}()
+	}
+
+	go func() {
+		workerWG.Wait()
+		close(results)
+	}()
+
+	collectResults(ctx, db, results)
+
+	producerWG.Wait()
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// errStopSearch is returned by produceSearchHits' emit callback to end a
+// search early once a repository has gone stale, without SearchFiles
+// treating that as a real failure.
+var errStopSearch = errors.New("stop search: repository past timeout")
+
+// produceSearchHits searches for filename via c, pushing every hit onto
+// hits as soon as it is found. It stops early once the repository behind
+// the last hit has gone longer than timeout hours without a commit. Because
+// hits are pushed as they are found rather than collected up front, a
+// cancelled ctx only drops hits not yet pushed.
+func produceSearchHits(ctx context.Context, c crawler.Crawler, filename string, timeout float64, hits chan<- crawler.FileHit) error {
+	count := 0
+
+	err := c.SearchFiles(ctx, filename, "flogo", func(hit crawler.FileHit) error {
+		select {
+		case hits <- hit:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		count++
+
+		if timeout == 0 {
+			return nil
+		}
+
+		age, err := c.LastCommitAge(ctx, hit.RepoFullName)
+		if err != nil {
+			return err
+		}
+		if age.Hours() > timeout {
+			logger.Info("stopping crawl, repository past timeout", "repo", hit.RepoFullName, "contrib_type", filename, "outcome", "skipped", "last_commit_hours", age.Hours())
+			return errStopSearch
+		}
+		return nil
+	})
+
+	if errors.Is(err, errStopSearch) {
+		err = nil
+	}
+	if err == nil {
+		logger.Info("search completed", "contrib_type", filename, "hits", count)
+	}
+	return err
+}
+
+// collectResults drains results into batches and inserts each batch into
+// the database in a single call, so a large crawl does not perform one
+// database round-trip per item. Batches are still written after ctx is
+// cancelled, so a cancelled crawl keeps every result that reached the
+// collector.
+func collectResults(ctx context.Context, db *database.Database, results <-chan map[string]interface{}) {
+	batch := make([]map[string]interface{}, 0, insertBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := db.InsertActs(context.WithoutCancel(ctx), batch); err != nil {
+			logger.Error("inserting batch failed", "batch_size", len(batch), "error", err.Error())
+		}
+		batch = batch[:0]
+	}
+
+	for result := range results {
+		batch = append(batch, result)
+		if len(batch) >= insertBatchSize {
+			flush()
+		}
+	}
+	flush()
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/retgits/fdio/crawler"
+)
+
+// streamingFakeCrawler emits a fixed list of hits, one at a time, the same
+// way a real backend streams results page by page.
+type streamingFakeCrawler struct {
+	hits []crawler.FileHit
+}
+
+func (f *streamingFakeCrawler) SearchFiles(ctx context.Context, filename, query string, emit func(crawler.FileHit) error) error {
+	for _, hit := range f.hits {
+		if err := emit(hit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *streamingFakeCrawler) FetchRaw(ctx context.Context, hit crawler.FileHit) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *streamingFakeCrawler) LastCommitAge(ctx context.Context, repoFullName string) (time.Duration, error) {
+	return 0, nil
+}
+
+func TestProduceSearchHitsForwardsAllHits(t *testing.T) {
+	c := &streamingFakeCrawler{hits: []crawler.FileHit{
+		{RepoFullName: "a/a", Path: "activity.json"},
+		{RepoFullName: "b/b", Path: "activity.json"},
+		{RepoFullName: "c/c", Path: "activity.json"},
+	}}
+
+	hits := make(chan crawler.FileHit, len(c.hits))
+	if err := produceSearchHits(context.Background(), c, "activity.json", 0, hits); err != nil {
+		t.Fatalf("produceSearchHits returned error: %v", err)
+	}
+	close(hits)
+
+	var got int
+	for range hits {
+		got++
+	}
+	if got != len(c.hits) {
+		t.Errorf("produceSearchHits forwarded %d hits, want %d", got, len(c.hits))
+	}
+}
+
+// TestProduceSearchHitsStopsOnCancelledContext guards against the
+// regression where SearchFiles collected every hit before returning: with
+// an already-cancelled context and an unbuffered channel, a producer that
+// still tries to push its first hit before checking ctx would deadlock
+// instead of returning ctx.Err() immediately.
+func TestProduceSearchHitsStopsOnCancelledContext(t *testing.T) {
+	c := &streamingFakeCrawler{hits: []crawler.FileHit{
+		{RepoFullName: "a/a", Path: "activity.json"},
+		{RepoFullName: "b/b", Path: "activity.json"},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hits := make(chan crawler.FileHit)
+	err := produceSearchHits(ctx, c, "activity.json", 0, hits)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("produceSearchHits returned %v, want context.Canceled", err)
+	}
+}
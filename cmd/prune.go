@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/retgits/fdio/database"
+	"github.com/spf13/cobra"
+	xmlpath "gopkg.in/xmlpath.v2"
+)
+
+// pruneMaxAge is the default threshold after which a repository with no new
+// commits is considered stale.
+const pruneMaxAge = 365 * 24 * time.Hour
+
+var (
+	pruneMaxAgeFlag time.Duration
+	pruneDryRun     bool
+)
+
+// pruneCmd walks every URL already stored in the database and flags or
+// removes the ones that point at a repository that no longer exists,
+// has been archived, has moved, or has gone stale.
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Flag or remove dead, archived, moved or stale repositories from the database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := database.New(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return Prune(context.Background(), db, pruneMaxAgeFlag, pruneDryRun)
+	},
+}
+
+func init() {
+	pruneCmd.Flags().DurationVar(&pruneMaxAgeFlag, "max-age", pruneMaxAge, "maximum time a repository may go without a commit before it is flagged stale")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "print the actions prune would take without changing the database")
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// repoStatus classifies the outcome of inspecting a single stored repository.
+type repoStatus string
+
+const (
+	statusOK       repoStatus = "ok"
+	statusDead     repoStatus = "dead"
+	statusArchived repoStatus = "archived"
+	statusStale    repoStatus = "stale"
+	statusMoved    repoStatus = "moved"
+)
+
+// Prune inspects every URL stored in db and, for each one that is no longer
+// healthy, either flags its row with the new status or, for a moved
+// repository, rewrites its URL. In dry-run mode it only logs what it would
+// have done.
+func Prune(ctx context.Context, db *database.Database, maxAge time.Duration, dryRun bool) error {
+	urls, err := db.ListURLs()
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		start := time.Now()
+		status, movedTo, err := inspectRepo(ctx, url, maxAge)
+		durationMS := time.Since(start).Milliseconds()
+		if err != nil {
+			logger.Error("error while inspecting repository", "repo", url, "outcome", "error", "error", err.Error(), "duration_ms", durationMS)
+			continue
+		}
+
+		switch status {
+		case statusOK:
+			continue
+		case statusMoved:
+			logger.Info("repository moved", "repo", url, "outcome", string(status), "moved_to", movedTo, "duration_ms", durationMS)
+			if dryRun {
+				continue
+			}
+			if err := db.UpdateURL(url, movedTo); err != nil {
+				logger.Error("error while updating repository url", "repo", url, "outcome", "error", "error", err.Error())
+			}
+		default:
+			logger.Info("repository flagged", "repo", url, "outcome", string(status), "duration_ms", durationMS)
+			if dryRun {
+				continue
+			}
+			if err := db.SetStatus(url, string(status)); err != nil {
+				logger.Error("error while flagging repository", "repo", url, "outcome", "error", "error", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// inspectRepo classifies a single stored URL. The stored URL points several
+// directories deep into the repository, so it resolves the repository's own
+// root URL first and checks that instead; otherwise an unrelated rename or
+// removal of that one subdirectory would misclassify a perfectly live repo.
+// It issues a cheap HEAD request first to catch deletions (404) and
+// redirects (301) without downloading the page body, then falls back to
+// checkLastUpdate's xmlpath approach to look for the archived-repository
+// banner and the last-commit <time-ago> element.
+func inspectRepo(ctx context.Context, url string, maxAge time.Duration) (repoStatus, string, error) {
+	rootURL := repoRootURL(url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rootURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	// The shared client follows redirects on its own, which would hide the
+	// 301 checked for below, so the HEAD request stops at the first response.
+	noRedirect := *httpClient
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	head, err := noRedirect.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	head.Body.Close()
+
+	switch head.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return statusDead, "", nil
+	case http.StatusMovedPermanently:
+		return statusMoved, head.Header.Get("Location"), nil
+	}
+
+	res, err := executeRequest(ctx, rootURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	xmlroot, err := xmlpath.ParseHTML(res.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	if xmlpath.MustCompile(`//*[contains(text(), "This repository has been archived")]`).Exists(xmlroot) {
+		return statusArchived, "", nil
+	}
+
+	age, ok := lastCommitAgeFromHTML(xmlroot)
+	if ok && time.Duration(age*float64(time.Hour)) > maxAge {
+		return statusStale, "", nil
+	}
+
+	return statusOK, "", nil
+}
+
+// repoRootURL strips the browsable directory suffix off a stored
+// contribution URL, returning the repository's own landing page. It
+// recognizes the directory-URL shapes fdio's crawler backends build:
+// GitHub's "/tree/<ref>/...", GitLab's "/-/tree/<ref>/...", and Gitea's
+// "/src/branch/<ref>/...". A URL matching none of them is returned as-is.
+func repoRootURL(itemURL string) string {
+	for _, marker := range []string{"/-/tree/", "/tree/", "/src/branch/"} {
+		if idx := strings.Index(itemURL, marker); idx != -1 {
+			return itemURL[:idx]
+		}
+	}
+	return itemURL
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRepoRootURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "github", in: "https://github.com/owner/repo/tree/master/src/activities/log", want: "https://github.com/owner/repo"},
+		{name: "gitlab", in: "https://gitlab.com/owner/repo/-/tree/main/src", want: "https://gitlab.com/owner/repo"},
+		{name: "gitea", in: "https://gitea.com/owner/repo/src/branch/main/src", want: "https://gitea.com/owner/repo"},
+		{name: "no marker", in: "https://example.com/owner/repo", want: "https://example.com/owner/repo"},
+	}
+
+	for _, tt := range tests {
+		if got := repoRootURL(tt.in); got != tt.want {
+			t.Errorf("%s: repoRootURL(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInspectRepoDead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	status, _, err := inspectRepo(context.Background(), srv.URL+"/tree/master/src", time.Hour)
+	if err != nil {
+		t.Fatalf("inspectRepo returned error: %v", err)
+	}
+	if status != statusDead {
+		t.Errorf("inspectRepo status = %q, want %q", status, statusDead)
+	}
+}
+
+// TestInspectRepoChecksRootNotDeepLink guards against the regression where
+// inspectRepo requested the stored per-contribution deep link instead of
+// the repository's own root.
+func TestInspectRepoChecksRootNotDeepLink(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write([]byte(`<html><body></body></html>`))
+	}))
+	defer srv.Close()
+
+	if _, _, err := inspectRepo(context.Background(), srv.URL+"/tree/master/src/activities/log", time.Hour); err != nil {
+		t.Fatalf("inspectRepo returned error: %v", err)
+	}
+
+	for _, path := range requested {
+		if path != "" && path != "/" {
+			t.Errorf("inspectRepo requested %q, want the repository root instead of a deep link", path)
+		}
+	}
+}
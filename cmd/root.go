@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	githubToken string
+	dbPath      string
+	logFormat   string
+)
+
+// logger is the structured logger every command uses to report per-repo
+// outcomes. It defaults to text output and is reconfigured from
+// --log-format once cobra has parsed flags, so commands must log through it
+// rather than the standard log package.
+var logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// rootCmd is the base command that every other fdio command hangs off of.
+var rootCmd = &cobra.Command{
+	Use:   "fdio",
+	Short: "fdio finds Flogo activities and triggers across GitHub, GitLab and Gitea",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		configureLogger()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&githubToken, "github-token", "", "GitHub personal access token used to authenticate search requests")
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "fdio.db", "path to the fdio database")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log output format: text or json")
+
+	rootCmd.AddCommand(crawlCmd)
+}
+
+// configureLogger builds the package-level logger according to --log-format,
+// so a crawl can be piped into log tooling that expects one JSON object per
+// line, or read directly on a terminal.
+func configureLogger() {
+	var handler slog.Handler
+	if logFormat == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, nil)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, nil)
+	}
+	logger = slog.New(handler)
+}
+
+// Execute runs the root command and is called from main.
+func Execute() error {
+	return rootCmd.Execute()
+}
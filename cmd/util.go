@@ -3,269 +3,126 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/nareix/curl"
 	toml "github.com/pelletier/go-toml"
-	"github.com/retgits/fdio/database"
-	"github.com/tomnomnom/linkheader"
+	"github.com/retgits/fdio/crawler"
 	xmlpath "gopkg.in/xmlpath.v2"
 )
 
-const (
-	githubRootEndpoint        string = "https://api.github.com"
-	githubSearchEndpoint      string = "/search/code"
-	githubActivitySearchQuery string = "sort=indexed&order=desc&q=filename%3Aactivity.json+flogo"
-	githubTriggerSearchQuery  string = "sort=indexed&order=desc&q=filename%3Atrigger.json+flogo"
-)
+// httpClient is shared by executeRequest so every call gets the same
+// connect/download timeouts without paying for a new client per request.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
 
-// executeRequest executes an HTTP request
-func executeRequest(URL string, headers http.Header) (curl.Response, error) {
-	// Prepare the cURL request
-	req := curl.Get(URL)
+// executeRequest executes an HTTP GET request against URL, honoring ctx
+// cancellation and deadlines.
+func executeRequest(ctx context.Context, URL string, headers http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	// Optionally add the HTTP headers
 	if headers != nil {
-		req.Headers = headers
+		req.Header = headers
 	}
 
-	// Set timeouts
-	// DialTimeout is the TCP Connection Timeout
-	// Timeout is the Download Timeout
-	req.DialTimeout(time.Second * 10)
-	req.Timeout(time.Second * 30)
-
-	// Specify a progress monitor, otherwise it doesn't work
-	req.Progress(func(p curl.ProgressStatus) {}, time.Second)
+	return httpClient.Do(req)
+}
 
+// checkLastUpdate sends an HTTP request to the HTML URL of a GitHub repository
+// and retrieves the last commit date.
+func checkLastUpdate(ctx context.Context, URL string) (float64, error) {
 	// Execute the request and return the result
-	res, err := req.Do()
+	res, err := executeRequest(ctx, URL, nil)
 	if err != nil {
-		return res, err
+		return 0, err
 	}
+	defer res.Body.Close()
 
-	// Return values
-	return res, nil
-}
-
-// Crawl will search on GitHub for activity.json or trigger.json files that are related to Flogo
-func Crawl(httpHeader http.Header, db *database.Database, timeout float64, contribType string) error {
-	githubSearchQuery := ""
-	if contribType == "Trigger" {
-		githubSearchQuery = githubTriggerSearchQuery
-	} else {
-		githubSearchQuery = githubActivitySearchQuery
+	xmlroot, xmlerr := xmlpath.ParseHTML(res.Body)
+	if xmlerr != nil {
+		return 0, xmlerr
 	}
 
-	// Get first page of activities
-	URL := fmt.Sprintf("%s%s?%s", githubRootEndpoint, githubSearchEndpoint, githubSearchQuery)
-	log.Printf("Send request to %s", URL)
-	response, err := executeRequest(URL, httpHeader)
-	if err != nil {
-		return err
+	age, ok := lastCommitAgeFromHTML(xmlroot)
+	if !ok {
+		return 0, fmt.Errorf("could not find last update of %s", URL)
 	}
 
-	// Unmarshal the JSON response
-	var responseBody map[string]interface{}
-	byteArray := []byte(response.Body)
-	if err = json.Unmarshal(byteArray, &responseBody); err != nil {
-		return err
-	}
+	return age, nil
+}
 
-	// Collect the items in this set
-	arrayMap, err := prepareItems(responseBody["items"].([]interface{}))
-	if err != nil {
-		return fmt.Errorf("error while converting response to array: %s", err.Error())
+// lastCommitAgeFromHTML extracts the age, in hours, of the last commit from
+// the <time-ago> element GitHub renders on a repository's HTML landing page.
+// It is factored out of checkLastUpdate so callers that already parsed the
+// page for another reason, like the prune command checking for an archived
+// banner, do not have to fetch it a second time.
+func lastCommitAgeFromHTML(xmlroot *xmlpath.Node) (float64, bool) {
+	value, ok := xmlpath.MustCompile(`//time-ago`).String(xmlroot)
+	if !ok {
+		return 0, false
 	}
 
-	// Store them in the database
-	db.InsertActs(arrayMap)
+	t, err := time.Parse("Jan 02, 2006", value)
 	if err != nil {
-		return fmt.Errorf("error while loading data into the database: %s", err.Error())
-	}
-
-	if timeout != 0 {
-		lastItem := arrayMap[len(arrayMap)-1]
-		lastURL := lastItem["url"].(string)
-		idx := strings.Index(lastURL, "/tree")
-		update, err := checkLastUpdate(lastURL[:idx])
-		if err != nil {
-			return err
-		}
-		// If update is larger than timeout it means the last update to the last checked
-		// repository was longer than the timeout we set. In that case we don't need to
-		// scan any further
-		if update > timeout {
-			log.Printf("Maximum timeout reached. Last repo update was %v hours\n", update)
-			return nil
-		}
-	}
-
-	// Check how many pages exist
-	var pages int
-	links := linkheader.Parse(response.Headers.Get("Link"))
-	for _, link := range links {
-		if link.Rel == "last" {
-			pages, _ = strconv.Atoi(link.URL[strings.Index(link.URL, "page=")+5:])
-		}
-	}
-
-	log.Printf("Found a total number of %v pages\n", pages)
-
-	for i := 2; i <= pages; i++ {
-		// Execute the request
-		URL := fmt.Sprintf("%s%s?%s&page=%v", githubRootEndpoint, githubSearchEndpoint, githubSearchQuery, i)
-		log.Printf("Send request to %s", URL)
-		response, err := executeRequest(URL, httpHeader)
-		if err != nil {
-			return err
-		}
-
-		// Unmarshal the JSON response
-		var responseBody map[string]interface{}
-		byteArray := []byte(response.Body)
-		if err = json.Unmarshal(byteArray, &responseBody); err != nil {
-			return err
-		}
-
-		// Collect the items in this set
-		arrayMap, err := prepareItems(responseBody["items"].([]interface{}))
-		if err != nil {
-			return fmt.Errorf("error while converting response to array: %s", err.Error())
-		}
-
-		// Store them in the database
-		db.InsertActs(arrayMap)
-		if err != nil {
-			return fmt.Errorf("error while loading data into the database: %s", err.Error())
-		}
-
-		if timeout != 0 {
-			lastItem := arrayMap[len(arrayMap)-1]
-			lastURL := lastItem["url"].(string)
-			idx := strings.Index(lastURL, "/tree")
-			update, err := checkLastUpdate(lastURL[:idx])
-			if err != nil {
-				return err
-			}
-			// If update is larger than timeout it means the last update to the last checked
-			// repository was longer than the timeout we set. In that case we don't need to
-			// scan any further
-			if update > timeout {
-				log.Printf("Maximum timeout reached. Last repo update was %v hours\n", update)
-				return nil
-			}
-		}
-
-		// Wait for 5 seconds so the GitHub search API limit won't be breached
-		time.Sleep(5 * time.Second)
+		return 0, false
 	}
 
-	return nil
+	return time.Since(t).Hours(), true
 }
 
-// checkLastUpdate sends an HTTP request to the HTML URL of a GitHub repository
-// and retrieves the last commit date.
-func checkLastUpdate(URL string) (float64, error) {
-	// Execute the request and return the result
-	res, err := executeRequest(URL, nil)
+// prepareItem takes a single file hit found by a Crawler, fetches the raw
+// activity.json/trigger.json content it points to and turns it into a
+// map[string]interface{} ready to be added to the database. It returns a nil
+// map (and no error) when the content has no type or no name; content that
+// cannot be fetched or parsed as JSON is returned as an error.
+func prepareItem(ctx context.Context, c crawler.Crawler, hit crawler.FileHit) (map[string]interface{}, error) {
+	raw, err := c.FetchRaw(ctx, hit)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("error while calling %s: %s", hit.RawURL, err.Error())
 	}
 
-	reader := strings.NewReader(res.Body)
-	xmlroot, xmlerr := xmlpath.ParseHTML(reader)
-
-	if xmlerr != nil {
-		return 0, err
+	// Unmarshal the JSON response
+	var responseBody map[string]interface{}
+	if err = json.Unmarshal(raw, &responseBody); err != nil {
+		return nil, fmt.Errorf("error while calling %s: %s", hit.RawURL, err.Error())
 	}
 
-	var xpath string
-	xpath = `//time-ago`
-	path := xmlpath.MustCompile(xpath)
-	if value, ok := path.String(xmlroot); ok {
-		layout := "Jan 02, 2006"
-		t, _ := time.Parse(layout, value)
-		duration := time.Since(t)
-		return duration.Hours(), nil
+	if responseBody["type"] == nil || responseBody["name"] == nil {
+		return nil, nil
 	}
 
-	return 0, fmt.Errorf("could not find last update of %s", URL)
-}
-
-// prepareItems takes the raw response from the GitHub search API and turns it into an
-// array of map[string]interface{} so it can be added to the database
-func prepareItems(items []interface{}) ([]map[string]interface{}, error) {
-	datamap := make([]map[string]interface{}, len(items))
-
-	for idx, item := range items {
-		// For each item we need some metadata
-		// the full_name of the repository contains both the repository name as
-		// well as the name of the owner
-		project := item.(map[string]interface{})
-		repository := project["repository"].(map[string]interface{})
-		contentURL := strings.Replace(project["html_url"].(string), "github.com", "raw.githubusercontent.com", 1)
-		contentURL = strings.Replace(contentURL, "/blob", "", 1)
-
-		// Get the content of the actual file
-		response, err := executeRequest(contentURL, nil)
+	// Get the project type
+	projectType := responseBody["type"].(string)
+	projectType = projectType[6:]
+	projectType = strings.Replace(projectType, ":", "", -1)
 
-		// Unmarshal the JSON response
-		var responseBody map[string]interface{}
-		byteArray := []byte(response.Body)
-		if err = json.Unmarshal(byteArray, &responseBody); err != nil {
-			log.Printf("error while calling %s: %s", contentURL, err.Error())
-		}
-
-		if err == nil && responseBody["type"] != nil {
-			// Get the project path without activity.json at the end
-			projectPath := project["path"].(string)
-			if strings.Contains(projectPath, "activity.json") {
-				projectPath = projectPath[:len(projectPath)-13]
-			} else {
-				projectPath = projectPath[:len(projectPath)-12]
-			}
-
-			// Get the project type
-			projectType := responseBody["type"].(string)
-			projectType = projectType[6:]
-			projectType = strings.Replace(projectType, ":", "", -1)
+	// Set author to unknown if it doesn't exist
+	if responseBody["author"] == nil {
+		responseBody["author"] = "Unknown"
+	}
 
-			// Set author to unknown if it doesn't exist
-			if responseBody["author"] == nil {
-				responseBody["author"] = "Unknown"
-			}
+	// Set an empty string if the description doesn't exist
+	if responseBody["description"] == nil {
+		responseBody["description"] = ""
+	}
 
-			// Set an empty string if the description doesn't exist
-			if responseBody["description"] == nil {
-				responseBody["description"] = ""
-			}
+	tempMap := make(map[string]interface{})
+	tempMap["name"] = responseBody["name"].(string)
+	tempMap["type"] = projectType
+	tempMap["description"] = responseBody["description"].(string)
+	tempMap["url"] = hit.DirURL
+	tempMap["uploadedon"] = ""
+	tempMap["author"] = responseBody["author"].(string)
+	tempMap["showcase"] = ""
 
-			if responseBody["name"] != nil {
-				tempMap := make(map[string]interface{})
-				tempMap["name"] = responseBody["name"].(string)
-				tempMap["type"] = projectType
-				tempMap["description"] = responseBody["description"].(string)
-				tempMap["url"] = fmt.Sprintf("https://github.com/%s/tree/master/%s", repository["full_name"].(string), projectPath)
-				tempMap["uploadedon"] = ""
-				tempMap["author"] = responseBody["author"].(string)
-				tempMap["showcase"] = ""
-				datamap[idx] = tempMap
-				// For debug
-				tempNameKey := strings.Replace(tempMap["name"].(string), " ", "", -1)
-				tempNameKey = strings.ToLower(tempNameKey)
-				tempKey := fmt.Sprintf("%s/%s", tempMap["author"].(string), tempNameKey)
-				log.Printf("Added %s to the list", tempKey)
-			}
-		}
-	}
-	return datamap, nil
+	return tempMap, nil
 }
 
 // TomlTreeToMap converts a toml tree to an array of map[string]interface{}. It does so
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/retgits/fdio/crawler"
+)
+
+// fakeCrawler is a minimal crawler.Crawler whose FetchRaw always returns a
+// fixed body, so prepareItem's handling of the response can be tested
+// without a real forge.
+type fakeCrawler struct {
+	raw []byte
+}
+
+func (f *fakeCrawler) SearchFiles(ctx context.Context, filename, query string, emit func(crawler.FileHit) error) error {
+	return nil
+}
+
+func (f *fakeCrawler) FetchRaw(ctx context.Context, hit crawler.FileHit) ([]byte, error) {
+	return f.raw, nil
+}
+
+func (f *fakeCrawler) LastCommitAge(ctx context.Context, repoFullName string) (time.Duration, error) {
+	return 0, nil
+}
+
+func TestPrepareItemStoresHitDirURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		dirURL string
+	}{
+		{name: "github", dirURL: "https://github.com/owner/repo/tree/master/src"},
+		{name: "gitlab", dirURL: "https://gitlab.com/owner/repo/-/tree/main/src"},
+		{name: "gitea", dirURL: "https://gitea.com/owner/repo/src/branch/main/src"},
+	}
+
+	c := &fakeCrawler{raw: []byte(`{"type":"flogo:activity","name":"log"}`)}
+
+	for _, tt := range tests {
+		hit := crawler.FileHit{Path: "src/activity.json", DirURL: tt.dirURL}
+
+		item, err := prepareItem(context.Background(), c, hit)
+		if err != nil {
+			t.Fatalf("%s: prepareItem returned error: %v", tt.name, err)
+		}
+		if item["url"] != tt.dirURL {
+			t.Errorf("%s: url = %q, want %q", tt.name, item["url"], tt.dirURL)
+		}
+	}
+}
+
+func TestPrepareItemSkipsUnusableContent(t *testing.T) {
+	c := &fakeCrawler{raw: []byte(`{"description":"no type or name"}`)}
+
+	item, err := prepareItem(context.Background(), c, crawler.FileHit{Path: "src/activity.json"})
+	if err != nil {
+		t.Fatalf("prepareItem returned error: %v", err)
+	}
+	if item != nil {
+		t.Errorf("prepareItem = %v, want nil", item)
+	}
+}
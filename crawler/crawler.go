@@ -0,0 +1,80 @@
+// Package crawler abstracts the forge-specific operations fdio needs to
+// discover Flogo activities and triggers, so the crawl command is not tied
+// to any single code-hosting platform.
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/retgits/fdio/httpcache"
+)
+
+// FileHit is a single filename match returned by a forge's code search.
+type FileHit struct {
+	// RepoFullName identifies the repository the hit belongs to, e.g. "owner/repo".
+	RepoFullName string
+	// Path is the location of the matched file within the repository.
+	Path string
+	// RawURL is the URL FetchRaw retrieves the file content from.
+	RawURL string
+	// HTMLURL is the forge's web UI URL for browsing the file.
+	HTMLURL string
+	// DirURL is the forge's web UI URL for browsing the file's containing
+	// directory. It is the link fdio stores as a contribution's showcase
+	// URL, built by the backend itself since only it knows the route its
+	// forge uses to list a directory.
+	DirURL string
+}
+
+// Crawler is implemented by every forge backend fdio can crawl.
+type Crawler interface {
+	// SearchFiles finds files named filename whose content matches query,
+	// calling emit for every hit as soon as it is found rather than
+	// collecting the whole result set first. This lets a caller act on
+	// early hits while later pages are still being fetched, and means a
+	// cancelled ctx or an emit error only drops hits not yet emitted.
+	// SearchFiles stops and returns the first error emit returns.
+	SearchFiles(ctx context.Context, filename, query string, emit func(FileHit) error) error
+	// FetchRaw retrieves the raw content of a file hit.
+	FetchRaw(ctx context.Context, hit FileHit) ([]byte, error)
+	// LastCommitAge returns how long ago repoFullName last received a commit.
+	LastCommitAge(ctx context.Context, repoFullName string) (time.Duration, error)
+}
+
+// New returns the Crawler implementation for the named forge. token, when
+// non-empty, is used to authenticate requests against the forge's API.
+// rateLimitBuffer configures how many requests the GitHub backend keeps in
+// reserve before throttling; it is ignored by the other backends. cache, when
+// non-nil, is used by the GitHub backend to avoid re-downloading raw file
+// content that has not changed since the last crawl; it is ignored by the
+// other backends. logger, when non-nil, is used by the GitHub backend to
+// report failures that do not abort the crawl, such as a failed cache
+// write; it is ignored by the other backends and defaults to
+// slog.Default() when nil. An empty forge name defaults to "github".
+func New(forge, token string, rateLimitBuffer int, cache *httpcache.Cache, logger *slog.Logger) (Crawler, error) {
+	switch forge {
+	case "", "github":
+		return NewGitHub(token, rateLimitBuffer, cache, logger), nil
+	case "gitlab":
+		return NewGitLab(token), nil
+	case "gitea":
+		return NewGitea(token), nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q", forge)
+	}
+}
+
+// dirOf returns the slash-separated parent directory of p, or "" if p has
+// no parent (it lives at the repository root). Forge search results only
+// give us a repo-relative file path; backends use this to derive the
+// containing directory for FileHit.DirURL.
+func dirOf(p string) string {
+	if idx := strings.LastIndex(p, "/"); idx >= 0 {
+		return p[:idx]
+	}
+	return ""
+}
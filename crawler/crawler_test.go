@@ -0,0 +1,187 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNewDispatchesOnForge(t *testing.T) {
+	tests := []struct {
+		forge   string
+		want    interface{}
+		wantErr bool
+	}{
+		{forge: "", want: &GitHub{}},
+		{forge: "github", want: &GitHub{}},
+		{forge: "gitlab", want: &GitLab{}},
+		{forge: "gitea", want: &Gitea{}},
+		{forge: "bitbucket", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := New(tt.forge, "", 0, nil, nil)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("New(%q) expected an error, got none", tt.forge)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("New(%q) returned unexpected error: %v", tt.forge, err)
+		}
+		switch tt.want.(type) {
+		case *GitHub:
+			if _, ok := got.(*GitHub); !ok {
+				t.Errorf("New(%q) = %T, want *GitHub", tt.forge, got)
+			}
+		case *GitLab:
+			if _, ok := got.(*GitLab); !ok {
+				t.Errorf("New(%q) = %T, want *GitLab", tt.forge, got)
+			}
+		case *Gitea:
+			if _, ok := got.(*Gitea); !ok {
+				t.Errorf("New(%q) = %T, want *Gitea", tt.forge, got)
+			}
+		}
+	}
+}
+
+func TestGitHubFetchRaw(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"flogo:activity","name":"log"}`))
+	}))
+	defer srv.Close()
+
+	gh := NewGitHub("", 0, nil, nil)
+	body, err := gh.FetchRaw(context.Background(), FileHit{RawURL: srv.URL})
+	if err != nil {
+		t.Fatalf("FetchRaw returned error: %v", err)
+	}
+	if string(body) != `{"type":"flogo:activity","name":"log"}` {
+		t.Errorf("FetchRaw returned %q", body)
+	}
+}
+
+func TestGitLabFetchRaw(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type":"flogo:trigger","name":"timer"}`))
+	}))
+	defer srv.Close()
+
+	gl := NewGitLab("")
+	body, err := gl.FetchRaw(context.Background(), FileHit{RawURL: srv.URL})
+	if err != nil {
+		t.Fatalf("FetchRaw returned error: %v", err)
+	}
+	if string(body) != `{"type":"flogo:trigger","name":"timer"}` {
+		t.Errorf("FetchRaw returned %q", body)
+	}
+}
+
+func TestGitLabSearchFilesFollowsNextPage(t *testing.T) {
+	pages := [][]byte{
+		[]byte(`[{"project_id":1,"path":"src/activity.json","ref":"main"}]`),
+		[]byte(`[{"project_id":1,"path":"other/activity.json","ref":"main"}]`),
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/projects/1") && !strings.Contains(r.URL.Path, "repository") {
+			w.Write([]byte(`{"path_with_namespace":"owner/repo"}`))
+			return
+		}
+
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		idx, _ := strconv.Atoi(page)
+		if idx < len(pages) {
+			w.Header().Set("X-Next-Page", strconv.Itoa(idx+1))
+		}
+		w.Write(pages[idx-1])
+	}))
+	defer srv.Close()
+
+	gl := NewGitLab("")
+	gl.baseURL = srv.URL
+
+	var hits []FileHit
+	err := gl.SearchFiles(context.Background(), "activity.json", "flogo", func(hit FileHit) error {
+		hits = append(hits, hit)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchFiles returned error: %v", err)
+	}
+	if len(hits) != len(pages) {
+		t.Fatalf("SearchFiles returned %d hits, want %d across both pages", len(hits), len(pages))
+	}
+}
+
+func TestGiteaSearchFilesFiltersByFilename(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[
+			{"full_name":"owner/repo","path":"src/activity.json","default_branch":"main"},
+			{"full_name":"owner/repo","path":"README.md","default_branch":"main"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	gitea := NewGitea("")
+	gitea.baseURL = srv.URL
+
+	var hits []FileHit
+	err := gitea.SearchFiles(context.Background(), "activity.json", "flogo", func(hit FileHit) error {
+		hits = append(hits, hit)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchFiles returned error: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("SearchFiles returned %d hits, want 1", len(hits))
+	}
+	if hits[0].Path != "src/activity.json" {
+		t.Errorf("SearchFiles hit path = %q, want %q", hits[0].Path, "src/activity.json")
+	}
+}
+
+func TestGiteaSearchFilesFollowsShortPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		count := giteaSearchPageSize
+		if page != "1" {
+			count = 1
+		}
+
+		var items []string
+		for i := 0; i < count; i++ {
+			items = append(items, `{"full_name":"owner/repo","path":"src/activity.json","default_branch":"main"}`)
+		}
+		w.Write([]byte(`{"data":[` + strings.Join(items, ",") + `]}`))
+	}))
+	defer srv.Close()
+
+	gitea := NewGitea("")
+	gitea.baseURL = srv.URL
+
+	var hits []FileHit
+	err := gitea.SearchFiles(context.Background(), "activity.json", "flogo", func(hit FileHit) error {
+		hits = append(hits, hit)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchFiles returned error: %v", err)
+	}
+	if want := giteaSearchPageSize + 1; len(hits) != want {
+		t.Fatalf("SearchFiles returned %d hits across pages, want %d", len(hits), want)
+	}
+}
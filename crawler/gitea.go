@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	giteaRootEndpoint = "https://gitea.com/api/v1"
+	giteaWebRoot      = "https://gitea.com"
+)
+
+// Gitea is a Crawler backed by the Gitea REST and code search APIs.
+type Gitea struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGitea returns a Crawler for gitea.com. token, when non-empty, is sent
+// in an "Authorization: token" header on every request.
+func NewGitea(token string) *Gitea {
+	return &Gitea{token: token, baseURL: giteaRootEndpoint, httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (g *Gitea) do(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+	return g.httpClient.Do(req)
+}
+
+// giteaSearchPageSize is the page size requested from Gitea's code search.
+const giteaSearchPageSize = 50
+
+// SearchFiles implements Crawler using Gitea's repository code search,
+// filtering the results down to files named filename and emitting each one
+// as it is built. It walks pages until one comes back short of
+// giteaSearchPageSize, the signal Gitea's paginated endpoints use to mark
+// the last page.
+func (g *Gitea) SearchFiles(ctx context.Context, filename, query string, emit func(FileHit) error) error {
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/repos/search?q=%s&type=code&page=%d&limit=%d", g.baseURL, url.QueryEscape(query), page, giteaSearchPageSize)
+		res, err := g.do(ctx, endpoint)
+		if err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		var searchResult struct {
+			Data []struct {
+				FullName      string `json:"full_name"`
+				Path          string `json:"path"`
+				DefaultBranch string `json:"default_branch"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &searchResult); err != nil {
+			return err
+		}
+
+		for _, item := range searchResult.Data {
+			if !strings.HasSuffix(item.Path, filename) {
+				continue
+			}
+			rawURL := fmt.Sprintf("%s/repos/%s/raw/%s?ref=%s", g.baseURL, item.FullName, url.PathEscape(item.Path), item.DefaultBranch)
+			htmlURL := fmt.Sprintf("%s/%s/src/branch/%s/%s", giteaWebRoot, item.FullName, item.DefaultBranch, item.Path)
+			hit := FileHit{RepoFullName: item.FullName, Path: item.Path, RawURL: rawURL, HTMLURL: htmlURL, DirURL: giteaDirURL(item.FullName, item.DefaultBranch, item.Path)}
+			if err := emit(hit); err != nil {
+				return err
+			}
+		}
+
+		if len(searchResult.Data) < giteaSearchPageSize {
+			return nil
+		}
+	}
+}
+
+// giteaDirURL builds the browsable URL of a file's containing directory
+// using Gitea's unified "/src/branch/<ref>/<path>" route, which serves both
+// files and directories.
+func giteaDirURL(fullName, ref, filePath string) string {
+	if dir := dirOf(filePath); dir != "" {
+		return fmt.Sprintf("%s/%s/src/branch/%s/%s", giteaWebRoot, fullName, ref, dir)
+	}
+	return fmt.Sprintf("%s/%s/src/branch/%s", giteaWebRoot, fullName, ref)
+}
+
+// FetchRaw implements Crawler by downloading hit.RawURL as-is.
+func (g *Gitea) FetchRaw(ctx context.Context, hit FileHit) ([]byte, error) {
+	res, err := g.do(ctx, hit.RawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+// LastCommitAge implements Crawler using the repository's commits endpoint.
+func (g *Gitea) LastCommitAge(ctx context.Context, repoFullName string) (time.Duration, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/commits?limit=1", g.baseURL, repoFullName)
+	res, err := g.do(ctx, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var commits []struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := json.Unmarshal(body, &commits); err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		return 0, fmt.Errorf("no commits found for repository %s", repoFullName)
+	}
+	return time.Since(commits[0].Commit.Committer.Date), nil
+}
@@ -0,0 +1,244 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/retgits/fdio/httpcache"
+	"github.com/tomnomnom/linkheader"
+	xmlpath "gopkg.in/xmlpath.v2"
+)
+
+const (
+	githubRootEndpoint    = "https://api.github.com"
+	githubSearchEndpoint  = "/search/code"
+	githubRateLimitBuffer = 100
+)
+
+// GitHub is a Crawler backed by the github.com REST and code search APIs.
+type GitHub struct {
+	token           string
+	rateLimitBuffer int
+	httpClient      *http.Client
+	cache           *httpcache.Cache
+	logger          *slog.Logger
+}
+
+// NewGitHub returns a Crawler for github.com. token, when non-empty, is
+// sent in an "Authorization: token" header on every request. rateLimitBuffer is the number of
+// remaining requests to keep in reserve before throttling; a value of 0
+// falls back to githubRateLimitBuffer. cache, when non-nil, is consulted by
+// FetchRaw before re-downloading a raw file that has not changed. logger,
+// when nil, defaults to slog.Default() and reports failures, such as a
+// failed cache write, that do not abort the crawl.
+func NewGitHub(token string, rateLimitBuffer int, cache *httpcache.Cache, logger *slog.Logger) *GitHub {
+	if rateLimitBuffer == 0 {
+		rateLimitBuffer = githubRateLimitBuffer
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &GitHub{token: token, rateLimitBuffer: rateLimitBuffer, httpClient: &http.Client{Timeout: 30 * time.Second}, cache: cache, logger: logger}
+}
+
+// do sends a request with the given headers attached on top of
+// authentication, retrying with exponential backoff on 403/429 responses
+// and throttling ahead of the reset time when the remaining rate-limit
+// budget is low.
+func (g *GitHub) do(ctx context.Context, url string, headers http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+
+	backoff := time.Second
+	const maxAttempts = 5
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		res, err := g.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusTooManyRequests {
+			g.throttle(res.Header)
+			return res, nil
+		}
+		res.Body.Close()
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return nil, fmt.Errorf("giving up on %s after %d attempts due to rate limiting", url, maxAttempts)
+}
+
+// throttle sleeps until the rate limit resets when the remaining budget has
+// dropped to or below g.rateLimitBuffer.
+func (g *GitHub) throttle(headers http.Header) {
+	remaining, _ := strconv.Atoi(headers.Get("X-RateLimit-Remaining"))
+	resetSecs, _ := strconv.ParseInt(headers.Get("X-RateLimit-Reset"), 10, 64)
+	if remaining == 0 && resetSecs == 0 {
+		return
+	}
+	if remaining <= g.rateLimitBuffer {
+		if wait := time.Until(time.Unix(resetSecs, 0)); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+}
+
+// SearchFiles implements Crawler using GitHub's /search/code endpoint,
+// emitting each hit as its page is parsed so a caller can start acting on
+// early results, and so hits from already-fetched pages are not lost if a
+// later page fails or ctx is cancelled.
+func (g *GitHub) SearchFiles(ctx context.Context, filename, query string, emit func(FileHit) error) error {
+	searchQuery := fmt.Sprintf("sort=indexed&order=desc&q=filename%%3A%s+%s", filename, query)
+
+	page, pages := 1, 1
+	for page <= pages {
+		url := fmt.Sprintf("%s%s?%s&page=%d", githubRootEndpoint, githubSearchEndpoint, searchQuery, page)
+		res, err := g.do(ctx, url, nil)
+		if err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		var responseBody map[string]interface{}
+		if err := json.Unmarshal(body, &responseBody); err != nil {
+			return err
+		}
+
+		items, _ := responseBody["items"].([]interface{})
+		for _, raw := range items {
+			item, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			repository, _ := item["repository"].(map[string]interface{})
+			fullName, _ := repository["full_name"].(string)
+			path, _ := item["path"].(string)
+			htmlURL, _ := item["html_url"].(string)
+
+			rawURL := strings.Replace(htmlURL, "github.com", "raw.githubusercontent.com", 1)
+			rawURL = strings.Replace(rawURL, "/blob", "", 1)
+
+			hit := FileHit{RepoFullName: fullName, Path: path, RawURL: rawURL, HTMLURL: htmlURL, DirURL: githubDirURL(fullName, htmlURL, path)}
+			if err := emit(hit); err != nil {
+				return err
+			}
+		}
+
+		if page == 1 {
+			for _, link := range linkheader.Parse(res.Header.Get("Link")) {
+				if link.Rel == "last" {
+					pages, _ = strconv.Atoi(link.URL[strings.Index(link.URL, "page=")+5:])
+				}
+			}
+		}
+		page++
+	}
+
+	return nil
+}
+
+// githubDirURL turns a search hit's own html_url
+// (".../blob/<ref>/<path-to-file>") into the browsable URL of its containing
+// directory. GitHub's "blob" route renders a single file and 404s on a
+// directory, so this swaps in the "tree" route instead of just trimming the
+// filename off html_url.
+func githubDirURL(fullName, htmlURL, filePath string) string {
+	ref := strings.TrimSuffix(strings.TrimPrefix(htmlURL, fmt.Sprintf("https://github.com/%s/blob/", fullName)), "/"+filePath)
+	if dir := dirOf(filePath); dir != "" {
+		return fmt.Sprintf("https://github.com/%s/tree/%s/%s", fullName, ref, dir)
+	}
+	return fmt.Sprintf("https://github.com/%s/tree/%s", fullName, ref)
+}
+
+// FetchRaw implements Crawler by downloading hit.RawURL, reusing a cached
+// copy when the forge confirms nothing has changed since it was stored.
+func (g *GitHub) FetchRaw(ctx context.Context, hit FileHit) ([]byte, error) {
+	var cached httpcache.Entry
+	var haveCached bool
+	var conditional http.Header
+
+	if g.cache != nil {
+		if entry, ok := g.cache.Get(hit.RawURL); ok {
+			cached, haveCached = entry, true
+			conditional = http.Header{}
+			if entry.ETag != "" {
+				conditional.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				conditional.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+	}
+
+	res, err := g.do(ctx, hit.RawURL, conditional)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if haveCached && res.StatusCode == http.StatusNotModified {
+		return cached.Body, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.cache != nil {
+		entry := httpcache.Entry{Body: body, ETag: res.Header.Get("ETag"), LastModified: res.Header.Get("Last-Modified")}
+		if err := g.cache.Store(hit.RawURL, entry); err != nil {
+			g.logger.Error("error while caching raw content", "url", hit.RawURL, "error", err.Error())
+		}
+	}
+
+	return body, nil
+}
+
+// LastCommitAge implements Crawler by scraping the <time-ago> element off
+// the repository's HTML landing page, the same signal GitHub's own UI uses
+// to render "updated N days ago".
+func (g *GitHub) LastCommitAge(ctx context.Context, repoFullName string) (time.Duration, error) {
+	res, err := g.do(ctx, fmt.Sprintf("https://github.com/%s", repoFullName), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	xmlroot, err := xmlpath.ParseHTML(res.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := xmlpath.MustCompile(`//time-ago`).String(xmlroot)
+	if !ok {
+		return 0, fmt.Errorf("could not find last update of %s", repoFullName)
+	}
+
+	t, err := time.Parse("Jan 02, 2006", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(t), nil
+}
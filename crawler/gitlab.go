@@ -0,0 +1,176 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	gitlabRootEndpoint = "https://gitlab.com/api/v4"
+	gitlabWebRoot      = "https://gitlab.com"
+)
+
+// GitLab is a Crawler backed by the gitlab.com REST and blob search APIs.
+type GitLab struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGitLab returns a Crawler for gitlab.com. token, when non-empty, is
+// sent as a private token on every request.
+func NewGitLab(token string) *GitLab {
+	return &GitLab{token: token, baseURL: gitlabRootEndpoint, httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (g *GitLab) do(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+	return g.httpClient.Do(req)
+}
+
+// gitlabSearchPerPage is the page size requested from GitLab's blob search,
+// GitLab's own maximum for that endpoint.
+const gitlabSearchPerPage = 100
+
+// SearchFiles implements Crawler using GitLab's global blob search, scoped
+// to file content, filtering the results down to files named filename and
+// emitting each one as it is built. It follows the search's X-Next-Page
+// response header to walk every page rather than just the first.
+func (g *GitLab) SearchFiles(ctx context.Context, filename, query string, emit func(FileHit) error) error {
+	searchQuery := url.QueryEscape(fmt.Sprintf("filename:%s %s", filename, query))
+	paths := make(map[int]string)
+
+	page := 1
+	for page != 0 {
+		endpoint := fmt.Sprintf("%s/search?scope=blobs&search=%s&per_page=%d&page=%d", g.baseURL, searchQuery, gitlabSearchPerPage, page)
+		res, err := g.do(ctx, endpoint)
+		if err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		var blobs []struct {
+			ProjectID int    `json:"project_id"`
+			Path      string `json:"path"`
+			Ref       string `json:"ref"`
+		}
+		if err := json.Unmarshal(body, &blobs); err != nil {
+			return err
+		}
+
+		for _, blob := range blobs {
+			repoPath, ok := paths[blob.ProjectID]
+			if !ok {
+				repoPath, err = g.projectPath(ctx, blob.ProjectID)
+				if err != nil {
+					return err
+				}
+				paths[blob.ProjectID] = repoPath
+			}
+
+			rawURL := fmt.Sprintf("%s/projects/%d/repository/files/%s/raw?ref=%s", g.baseURL, blob.ProjectID, url.PathEscape(blob.Path), blob.Ref)
+			hit := FileHit{
+				RepoFullName: repoPath,
+				Path:         blob.Path,
+				RawURL:       rawURL,
+				HTMLURL:      fmt.Sprintf("%s/%s/-/blob/%s/%s", gitlabWebRoot, repoPath, blob.Ref, blob.Path),
+				DirURL:       gitlabDirURL(repoPath, blob.Ref, blob.Path),
+			}
+			if err := emit(hit); err != nil {
+				return err
+			}
+		}
+
+		page, _ = strconv.Atoi(res.Header.Get("X-Next-Page"))
+	}
+	return nil
+}
+
+// projectPath resolves projectID's namespaced path (e.g. "owner/repo"), the
+// form GitLab's web UI and commits API expect. The blobs search endpoint
+// only returns the numeric project ID, so a browsable link needs this extra
+// lookup; results are cached per call by the caller to avoid refetching the
+// same project for every hit it contributed.
+func (g *GitLab) projectPath(ctx context.Context, projectID int) (string, error) {
+	endpoint := fmt.Sprintf("%s/projects/%d", g.baseURL, projectID)
+	res, err := g.do(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	}
+	if err := json.Unmarshal(body, &project); err != nil {
+		return "", err
+	}
+	return project.PathWithNamespace, nil
+}
+
+// gitlabDirURL builds the browsable URL of a file's containing directory
+// from the project's namespaced path, using GitLab's "/-/tree/" route.
+func gitlabDirURL(pathWithNamespace, ref, filePath string) string {
+	if dir := dirOf(filePath); dir != "" {
+		return fmt.Sprintf("%s/%s/-/tree/%s/%s", gitlabWebRoot, pathWithNamespace, ref, dir)
+	}
+	return fmt.Sprintf("%s/%s/-/tree/%s", gitlabWebRoot, pathWithNamespace, ref)
+}
+
+// FetchRaw implements Crawler by downloading hit.RawURL as-is.
+func (g *GitLab) FetchRaw(ctx context.Context, hit FileHit) ([]byte, error) {
+	res, err := g.do(ctx, hit.RawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+// LastCommitAge implements Crawler using the project's commits endpoint.
+func (g *GitLab) LastCommitAge(ctx context.Context, repoFullName string) (time.Duration, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/repository/commits?per_page=1", g.baseURL, url.PathEscape(repoFullName))
+	res, err := g.do(ctx, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var commits []struct {
+		CommittedDate time.Time `json:"committed_date"`
+	}
+	if err := json.Unmarshal(body, &commits); err != nil {
+		return 0, err
+	}
+	if len(commits) == 0 {
+		return 0, fmt.Errorf("no commits found for project %s", repoFullName)
+	}
+	return time.Since(commits[0].CommittedDate), nil
+}
@@ -0,0 +1,130 @@
+// Package database stores the contributions fdio's crawl finds and the
+// health status prune assigns to the repository each one lives in.
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// row is a single stored contribution plus the status prune assigned to the
+// repository it was found in. A zero-value Status means the repository is
+// healthy.
+type row struct {
+	Item   map[string]interface{} `json:"item"`
+	Status string                 `json:"status,omitempty"`
+}
+
+// Database is a JSON-file-backed store of crawled contributions, keyed by
+// each one's showcase URL.
+type Database struct {
+	path string
+
+	mu   sync.Mutex
+	rows map[string]*row
+}
+
+// New opens the database at path, loading any rows already stored there. A
+// path that does not exist yet is treated as an empty database; it is
+// created on the first Close.
+func New(path string) (*Database, error) {
+	db := &Database{path: path, rows: make(map[string]*row)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return db, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return db, nil
+	}
+
+	if err := json.Unmarshal(data, &db.rows); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Close persists the database to its path.
+func (d *Database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data, err := json.Marshal(d.rows)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, data, 0o644)
+}
+
+// InsertActs stores a batch of crawled contributions, keyed by each item's
+// "url" field. An item with no usable "url" is skipped. It honors ctx
+// cancellation between items, so a cancelled crawl does not block on an
+// in-flight write of a large batch.
+func (d *Database) InsertActs(ctx context.Context, items []map[string]interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		url, _ := item["url"].(string)
+		if url == "" {
+			continue
+		}
+		d.rows[url] = &row{Item: item}
+	}
+	return nil
+}
+
+// ListURLs returns the showcase URL of every contribution currently stored,
+// for prune to walk and check the health of.
+func (d *Database) ListURLs() ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	urls := make([]string, 0, len(d.rows))
+	for url := range d.rows {
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
+
+// UpdateURL renames a stored row from oldURL to newURL, used by prune when
+// it discovers the repository behind oldURL has moved. It is a no-op if
+// oldURL is not stored.
+func (d *Database) UpdateURL(oldURL, newURL string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	r, ok := d.rows[oldURL]
+	if !ok {
+		return nil
+	}
+	delete(d.rows, oldURL)
+	r.Item["url"] = newURL
+	d.rows[newURL] = r
+	return nil
+}
+
+// SetStatus flags the row stored at url with status (e.g. "dead",
+// "archived", "stale"), the column prune uses to record a repository's
+// health without deleting its row outright. It is a no-op if url is not
+// stored.
+func (d *Database) SetStatus(url, status string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	r, ok := d.rows[url]
+	if !ok {
+		return nil
+	}
+	r.Status = status
+	return nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsertActsAndListURLs(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "fdio.db"))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	db.InsertActs(context.Background(), []map[string]interface{}{
+		{"url": "https://github.com/owner/repo", "name": "log"},
+		{"name": "no url, should be skipped"},
+	})
+
+	urls, err := db.ListURLs()
+	if err != nil {
+		t.Fatalf("ListURLs returned error: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://github.com/owner/repo" {
+		t.Errorf("ListURLs = %v, want [https://github.com/owner/repo]", urls)
+	}
+}
+
+func TestUpdateURLAndSetStatus(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "fdio.db"))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	db.InsertActs(context.Background(), []map[string]interface{}{{"url": "https://github.com/old/repo"}})
+
+	if err := db.UpdateURL("https://github.com/old/repo", "https://github.com/new/repo"); err != nil {
+		t.Fatalf("UpdateURL returned error: %v", err)
+	}
+	if err := db.SetStatus("https://github.com/new/repo", "dead"); err != nil {
+		t.Fatalf("SetStatus returned error: %v", err)
+	}
+
+	urls, err := db.ListURLs()
+	if err != nil {
+		t.Fatalf("ListURLs returned error: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://github.com/new/repo" {
+		t.Errorf("ListURLs = %v, want [https://github.com/new/repo]", urls)
+	}
+}
+
+func TestInsertActsStopsOnCancelledContext(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "fdio.db"))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := db.InsertActs(ctx, []map[string]interface{}{{"url": "https://github.com/owner/repo"}}); err == nil {
+		t.Fatal("InsertActs with a cancelled context returned nil error, want context.Canceled")
+	}
+
+	urls, err := db.ListURLs()
+	if err != nil {
+		t.Fatalf("ListURLs returned error: %v", err)
+	}
+	if len(urls) != 0 {
+		t.Errorf("ListURLs = %v, want none stored after a cancelled InsertActs", urls)
+	}
+}
+
+func TestCloseAndReopenPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fdio.db")
+
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	db.InsertActs(context.Background(), []map[string]interface{}{{"url": "https://github.com/owner/repo"}})
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	reopened, err := New(path)
+	if err != nil {
+		t.Fatalf("New returned error on reopen: %v", err)
+	}
+	urls, err := reopened.ListURLs()
+	if err != nil {
+		t.Fatalf("ListURLs returned error: %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://github.com/owner/repo" {
+		t.Errorf("ListURLs after reopen = %v, want [https://github.com/owner/repo]", urls)
+	}
+}
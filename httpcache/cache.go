@@ -0,0 +1,84 @@
+// Package httpcache implements a small on-disk HTTP response cache keyed by
+// URL, so a crawler does not have to re-download a file whose content has
+// not changed since the last run.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is a single cached response.
+type Entry struct {
+	// Body is the cached response body.
+	Body []byte
+	// ETag is the response's ETag header, if any, sent back as
+	// If-None-Match on the next request.
+	ETag string
+	// LastModified is the response's Last-Modified header, if any, sent
+	// back as If-Modified-Since on the next request.
+	LastModified string
+	// StoredAt is when the entry was written, used to enforce TTL.
+	StoredAt time.Time
+}
+
+// Cache is a filesystem-backed store of Entry values keyed by URL. Entries
+// older than TTL are treated as misses so a cache left on disk for a long
+// time does not serve stale content forever.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// New returns a Cache that stores entries under dir, creating it if
+// necessary. A ttl of 0 disables expiry.
+func New(dir string, ttl time.Duration) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, ttl: ttl}, nil
+}
+
+// Get returns the cached entry for url, if one exists and has not expired.
+func (c *Cache) Get(url string) (Entry, bool) {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return Entry{}, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false
+	}
+
+	if c.ttl > 0 && time.Since(entry.StoredAt) > c.ttl {
+		return Entry{}, false
+	}
+
+	return entry, true
+}
+
+// Store writes entry to the cache under url, stamping it with the current
+// time so TTL expiry can be evaluated later.
+func (c *Cache) Store(url string, entry Entry) error {
+	entry.StoredAt = time.Now()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path(url), data, 0o644)
+}
+
+// path returns the on-disk location of url's cache entry. URLs are hashed
+// rather than used as filenames directly since they can contain characters
+// that are not valid across filesystems.
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
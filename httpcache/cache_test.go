@@ -0,0 +1,66 @@
+package httpcache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheStoreAndGet(t *testing.T) {
+	c, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	entry := Entry{Body: []byte("hello"), ETag: `"abc"`}
+	if err := c.Store("https://example.com/f", entry); err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+
+	got, ok := c.Get("https://example.com/f")
+	if !ok {
+		t.Fatal("Get reported a miss for a URL that was just stored")
+	}
+	if string(got.Body) != "hello" || got.ETag != `"abc"` {
+		t.Errorf("Get returned %+v, want body %q and ETag %q", got, "hello", `"abc"`)
+	}
+}
+
+func TestCacheGetMiss(t *testing.T) {
+	c, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if _, ok := c.Get("https://example.com/missing"); ok {
+		t.Error("Get reported a hit for a URL that was never stored")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	if err := c.Store("https://example.com/f", Entry{Body: []byte("hello")}); err != nil {
+		t.Fatalf("Store returned error: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if _, ok := c.Get("https://example.com/f"); ok {
+		t.Error("Get reported a hit for an entry that should have expired")
+	}
+}
+
+func TestCachePathIsStableAndFilesystemSafe(t *testing.T) {
+	c := &Cache{dir: "/tmp/cache"}
+	got := c.path("https://example.com/a?b=c")
+	if filepath.Dir(got) != "/tmp/cache" {
+		t.Errorf("path() = %q, want it under /tmp/cache", got)
+	}
+	if got != c.path("https://example.com/a?b=c") {
+		t.Error("path() is not stable for the same URL")
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"log"
+
+	"github.com/retgits/fdio/cmd"
+)
+
+func main() {
+	if err := cmd.Execute(); err != nil {
+		log.Fatal(err)
+	}
+}